@@ -0,0 +1,89 @@
+package interceptors
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoffDelayBounds(t *testing.T) {
+	cfg := BackoffConfig{
+		BaseDelay: 1 * time.Second,
+		Factor:    1.6,
+		Jitter:    0.2,
+		MaxDelay:  10 * time.Second,
+	}
+	b := ExponentialBackoff{Config: cfg}
+
+	// retries=0: base*factor^0 = base, +/-20% jitter.
+	delay := b.Delay(0, 0)
+	if lo, hi := 800*time.Millisecond, 1200*time.Millisecond; delay < lo || delay > hi {
+		t.Fatalf("Delay(0, _) = %v, want in [%v, %v]", delay, lo, hi)
+	}
+
+	// A high retry count must be capped at MaxDelay(+/-jitter), not grow
+	// unbounded with the exponent.
+	delay = b.Delay(50, 0)
+	if max := cfg.MaxDelay + time.Duration(float64(cfg.MaxDelay)*cfg.Jitter); delay > max {
+		t.Fatalf("Delay(50, _) = %v, want capped near MaxDelay %v", delay, cfg.MaxDelay)
+	}
+}
+
+func TestLinearBackoffDelay(t *testing.T) {
+	b := LinearBackoff{BaseDelay: time.Second, Increment: 500 * time.Millisecond, MaxDelay: 3 * time.Second}
+
+	tests := []struct {
+		retries int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{1, 1500 * time.Millisecond},
+		{2, 2 * time.Second},
+		{10, 3 * time.Second}, // capped at MaxDelay
+	}
+	for _, tt := range tests {
+		if got := b.Delay(tt.retries, 0); got != tt.want {
+			t.Fatalf("Delay(%d, _) = %v, want %v", tt.retries, got, tt.want)
+		}
+	}
+}
+
+func TestDecorrelatedJitterBackoffDelay(t *testing.T) {
+	b := DecorrelatedJitterBackoff{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	// First call has no previous delay, so prev is treated as BaseDelay and
+	// the result must fall in [BaseDelay, BaseDelay*3].
+	delay := b.Delay(0, 0)
+	if delay < b.BaseDelay || delay > 3*b.BaseDelay {
+		t.Fatalf("Delay(_, 0) = %v, want in [%v, %v]", delay, b.BaseDelay, 3*b.BaseDelay)
+	}
+
+	// A large previous delay must still be capped at MaxDelay.
+	delay = b.Delay(0, 10*time.Second)
+	if delay > b.MaxDelay {
+		t.Fatalf("Delay(_, 10s) = %v, want capped at MaxDelay %v", delay, b.MaxDelay)
+	}
+	if delay < b.BaseDelay {
+		t.Fatalf("Delay(_, 10s) = %v, want at least BaseDelay %v", delay, b.BaseDelay)
+	}
+}
+
+func TestNodeRetryQueueDropsOldestOnOverflow(t *testing.T) {
+	q := newNodeRetryQueue(2)
+
+	q.push(retryItem{id: "a"})
+	q.push(retryItem{id: "b"})
+	q.push(retryItem{id: "c"}) // overflow: drops "a"
+
+	if got := q.droppedCount(); got != 1 {
+		t.Fatalf("droppedCount() = %d, want 1", got)
+	}
+
+	first, _ := q.pop()
+	if first.id != "b" {
+		t.Fatalf("pop() returned id %q, want %q (the oldest surviving item)", first.id, "b")
+	}
+	second, _ := q.pop()
+	if second.id != "c" {
+		t.Fatalf("pop() returned id %q, want %q", second.id, "c")
+	}
+}
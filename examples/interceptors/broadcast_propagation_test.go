@@ -0,0 +1,44 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// TestBroadcastIDSurvivesTheWire simulates what a real RPC does to
+// outgoingBroadcastContext's metadata: gRPC carries a client's outgoing
+// metadata to the server as incoming metadata. forwardedBroadcastID must read
+// it back on the other side, or every forwarded leg looks like a fresh root
+// broadcast and re-broadcasts forever.
+func TestBroadcastIDSurvivesTheWire(t *testing.T) {
+	outgoingCtx := outgoingBroadcastContext(context.Background(), "broadcast-7")
+
+	md, ok := metadata.FromOutgoingContext(outgoingCtx)
+	if !ok {
+		t.Fatal("outgoingBroadcastContext attached no outgoing gRPC metadata")
+	}
+
+	// This is what the receiving node's gRPC stack does to the metadata on
+	// arrival: it becomes the incoming metadata of the handler's context.
+	incomingCtx := metadata.NewIncomingContext(context.Background(), md)
+
+	id, forwarded := forwardedBroadcastID(incomingCtx, nil)
+	if !forwarded {
+		t.Fatal("forwardedBroadcastID did not find the ID carried over the wire")
+	}
+	if id != "broadcast-7" {
+		t.Fatalf("forwardedBroadcastID returned id %q, want %q", id, "broadcast-7")
+	}
+}
+
+// TestBroadcastIDAbsentWithoutMetadata confirms a context with no attached
+// broadcast metadata is correctly treated as originating a new broadcast,
+// the same as a client request that never went through
+// outgoingBroadcastContext.
+func TestBroadcastIDAbsentWithoutMetadata(t *testing.T) {
+	if _, forwarded := forwardedBroadcastID(context.Background(), nil); forwarded {
+		t.Fatal("forwardedBroadcastID reported a forwarded ID with no metadata present")
+	}
+}
@@ -0,0 +1,141 @@
+package interceptors
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBroadcastRegistryMarkNodeDoneCompletesOnLastNode(t *testing.T) {
+	r := newBroadcastRegistry(time.Minute)
+	ctx := r.register("b1", []string{"node-a", "node-b"})
+
+	r.markNodeDone("b1", "node-a", 2)
+	select {
+	case <-ctx.Done():
+		t.Fatal("broadcast context cancelled before every node finished")
+	default:
+	}
+
+	r.markNodeDone("b1", "node-b", 2)
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("broadcast context was not cancelled after every node finished")
+	}
+
+	// The entry completed (and was evicted) before this call, not while
+	// this call was blocked in wait's select - it must still resolve
+	// correctly instead of looking like an unknown id.
+	if err := r.wait(context.Background(), "b1"); err != nil {
+		t.Fatalf("wait after completion: %v", err)
+	}
+}
+
+func TestBroadcastRegistryCancelStopsWaiters(t *testing.T) {
+	r := newBroadcastRegistry(time.Minute)
+	broadcastCtx := r.register("b2", []string{"only-node"})
+
+	done := make(chan error, 1)
+	go func() { done <- r.wait(context.Background(), "b2") }()
+
+	if !r.cancel("b2") {
+		t.Fatal("cancel reported no in-flight broadcast for a registered id")
+	}
+
+	select {
+	case <-broadcastCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("cancel did not cancel the dispatch context")
+	}
+
+	// CancelBroadcast only cancels the dispatch context; the entry is still
+	// considered in flight until every node reports in via markNodeDone, so
+	// a waiter blocks until that happens (or its own ctx is done).
+	select {
+	case err := <-done:
+		t.Fatalf("wait returned before the cancelled broadcast's legs finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	r.markNodeDone("b2", "only-node", 1)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("wait after eviction: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("wait did not return after the cancelled broadcast's last node finished")
+	}
+}
+
+func TestBroadcastRegistryCancelUnknownID(t *testing.T) {
+	r := newBroadcastRegistry(time.Minute)
+	if r.cancel("does-not-exist") {
+		t.Fatal("cancel reported success for an id that was never registered")
+	}
+}
+
+func TestBroadcastRegistryEvictExpired(t *testing.T) {
+	r := newBroadcastRegistry(time.Millisecond)
+	ctx := r.register("b3", []string{"node-a", "node-b"})
+	r.markNodeDone("b3", "node-a", 2)
+
+	time.Sleep(5 * time.Millisecond)
+	r.evictExpired()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("evictExpired did not cancel an expired entry's context")
+	}
+
+	// wait is called after the TTL eviction already happened, not while
+	// blocked in its select - it must still distinguish a timed-out
+	// broadcast from an unknown id or one that truly completed.
+	err := r.wait(context.Background(), "b3")
+	timeoutErr, ok := err.(*BroadcastTimeoutError)
+	if !ok {
+		t.Fatalf("wait after TTL eviction: got %v, want *BroadcastTimeoutError", err)
+	}
+	if want := []string{"node-b"}; !slices.Equal(timeoutErr.Pending, want) {
+		t.Fatalf("BroadcastTimeoutError.Pending = %v, want %v", timeoutErr.Pending, want)
+	}
+}
+
+func TestBroadcastRegistryMarkNodeSkippedReportsSkippedError(t *testing.T) {
+	r := newBroadcastRegistry(time.Minute)
+	r.register("b5", []string{"node-a", "node-b"})
+
+	r.markNodeDone("b5", "node-a", 2)
+	r.markNodeSkipped("b5", "node-b", 2) // e.g. HealthChecker had marked it down
+
+	err := r.wait(context.Background(), "b5")
+	skippedErr, ok := err.(*BroadcastSkippedError)
+	if !ok {
+		t.Fatalf("wait after a skipped node finished: got %v, want *BroadcastSkippedError", err)
+	}
+	if want := []string{"node-b"}; !slices.Equal(skippedErr.Skipped, want) {
+		t.Fatalf("BroadcastSkippedError.Skipped = %v, want %v", skippedErr.Skipped, want)
+	}
+}
+
+func TestBroadcastRegistryReapsTerminalEntryAfterGracePeriod(t *testing.T) {
+	r := newBroadcastRegistry(time.Millisecond)
+	r.register("b4", []string{"only-node"})
+	r.markNodeDone("b4", "only-node", 1)
+
+	// Immediately after completion the entry is still around for a
+	// post-hoc wait to find.
+	if err := r.wait(context.Background(), "b4"); err != nil {
+		t.Fatalf("wait right after completion: %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	r.evictExpired()
+
+	if err := r.wait(context.Background(), "b4"); err == nil {
+		t.Fatal("wait succeeded for an id reaped after its terminal grace period")
+	}
+}
@@ -0,0 +1,141 @@
+package interceptors
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemDedupStoreSeenAndExpiry(t *testing.T) {
+	s := NewMemDedupStore()
+
+	if s.Seen("a") {
+		t.Fatal("Seen reported true for an id that was never marked")
+	}
+
+	s.Mark("a", time.Hour)
+	if !s.Seen("a") {
+		t.Fatal("Seen reported false right after Mark")
+	}
+
+	s.Mark("b", -time.Second) // already expired
+	if s.Seen("b") {
+		t.Fatal("Seen reported true for an id marked with an already-past deadline")
+	}
+}
+
+func TestLRUDedupStoreEvictsOldest(t *testing.T) {
+	s := NewLRUDedupStore(2)
+
+	s.Mark("a", time.Hour)
+	s.Mark("b", time.Hour)
+	s.Mark("c", time.Hour) // evicts "a", the least recently used
+
+	if s.Seen("a") {
+		t.Fatal("Seen reported true for an id evicted by capacity")
+	}
+	if !s.Seen("b") || !s.Seen("c") {
+		t.Fatal("Seen reported false for an id that should still be tracked")
+	}
+}
+
+func TestLRUDedupStoreSeenRefreshesRecency(t *testing.T) {
+	s := NewLRUDedupStore(2)
+
+	s.Mark("a", time.Hour)
+	s.Mark("b", time.Hour)
+	s.Seen("a") // touch "a" so "b" becomes the least recently used
+	s.Mark("c", time.Hour)
+
+	if !s.Seen("a") {
+		t.Fatal("Seen(\"a\") reported false after a refreshing Seen call should have spared it from eviction")
+	}
+	if s.Seen("b") {
+		t.Fatal("Seen(\"b\") reported true; it should have been evicted as the least recently used entry")
+	}
+}
+
+func TestFileDedupStoreSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup.log")
+
+	s, err := NewFileDedupStore(path)
+	if err != nil {
+		t.Fatalf("NewFileDedupStore: %v", err)
+	}
+	s.Mark("broadcast-1", time.Hour)
+	s.Mark("broadcast-2", time.Nanosecond) // expires almost immediately
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	time.Sleep(time.Millisecond)
+
+	// Simulate a restart: reopen the same log and replay it.
+	restarted, err := NewFileDedupStore(path)
+	if err != nil {
+		t.Fatalf("NewFileDedupStore after restart: %v", err)
+	}
+	defer restarted.Close()
+
+	if !restarted.Seen("broadcast-1") {
+		t.Fatal("a broadcast marked before the restart was forgotten, which would cause it to re-broadcast")
+	}
+	if restarted.Seen("broadcast-2") {
+		t.Fatal("an expired broadcast was replayed as still seen")
+	}
+	if restarted.Seen("broadcast-3") {
+		t.Fatal("Seen reported true for an id that was never marked")
+	}
+}
+
+// TestDedupKeyForContentSurvivesRetryAfterCrash simulates the scenario
+// dedupKeyFor exists for: this server dispatches a write, crashes before the
+// client gives up, and the client retries the identical logical write. The
+// retry arrives as a new *gorums.Message with a new message ID - a
+// per-RPCCall sequence number, not a property of the request - so a key
+// derived from that ID (as broadcastID is, for the registry and wire
+// propagation) would never match the original and the retry would be
+// re-broadcast to every peer. dedupKeyFor's content-derived key must
+// recognize the retry despite the new ID.
+func TestDedupKeyForContentSurvivesRetryAfterCrash(t *testing.T) {
+	store, err := NewFileDedupStore(filepath.Join(t.TempDir(), "dedup.log"))
+	if err != nil {
+		t.Fatalf("NewFileDedupStore: %v", err)
+	}
+	defer store.Close()
+
+	content := []byte(`write key="foo" value="bar"`) // stand-in for a marshaled proto request
+	originalKey := dedupKeyForContent(content)
+
+	if store.Seen(originalKey) {
+		t.Fatal("Seen reported true before the original dispatch was ever marked")
+	}
+	store.Mark(originalKey, time.Hour) // the original dispatch, before the crash
+
+	// The server crashes here. The client, having received no response,
+	// retries - same logical write, same content, but a brand new message
+	// ID assigned by the new RPCCall. The key must be computed from content
+	// alone to still match.
+	retryKey := dedupKeyForContent(content)
+	if retryKey != originalKey {
+		t.Fatalf("dedupKeyForContent(content) = %q on retry, want %q (the original key) - a new message ID must not change it", retryKey, originalKey)
+	}
+	if !store.Seen(retryKey) {
+		t.Fatal("Seen reported false for a retry of an already-dispatched write - this server would re-broadcast it to every peer, defeating DedupStore")
+	}
+}
+
+// TestDedupKeyForContentCollidesAcrossClients documents the trade-off
+// dedupKeyFor accepts in exchange for surviving a message-ID rotation on
+// retry: unlike broadcastID (keyed on the per-call message ID, so distinct
+// clients never collide), two different clients broadcasting byte-identical
+// requests hash to the same DedupStore key and are deduplicated against
+// each other.
+func TestDedupKeyForContentCollidesAcrossClients(t *testing.T) {
+	clientARequest := []byte(`write key="foo" value="bar"`)
+	clientBRequest := []byte(`write key="foo" value="bar"`) // byte-identical, different client
+
+	if dedupKeyForContent(clientARequest) != dedupKeyForContent(clientBRequest) {
+		t.Fatal("dedupKeyForContent produced different keys for byte-identical content from different clients")
+	}
+}
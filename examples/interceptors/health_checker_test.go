@@ -0,0 +1,89 @@
+package interceptors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHealthProbeOutcomeBlipDoesNotFlap(t *testing.T) {
+	errProbe := errors.New("dial timeout")
+
+	fails := int32(0)
+	for i := int32(1); i < maxConsecutiveHealthTimeouts; i++ {
+		_, newFails, ok := healthProbeOutcome(fails, errProbe, 0)
+		if ok {
+			t.Fatalf("healthProbeOutcome reported ok after %d consecutive failure(s), want it to wait for %d", i, maxConsecutiveHealthTimeouts)
+		}
+		if newFails != i {
+			t.Fatalf("healthProbeOutcome returned fails=%d after %d failure(s), want %d", newFails, i, i)
+		}
+		fails = newFails
+	}
+}
+
+func TestHealthProbeOutcomeTransitionsToNotServingAfterThreshold(t *testing.T) {
+	errProbe := errors.New("dial timeout")
+
+	next, newFails, ok := healthProbeOutcome(maxConsecutiveHealthTimeouts-1, errProbe, 0)
+	if !ok {
+		t.Fatal("healthProbeOutcome did not report ok once the failure threshold was reached")
+	}
+	if next != HealthNotServing {
+		t.Fatalf("healthProbeOutcome state = %v, want HealthNotServing", next)
+	}
+	if newFails != maxConsecutiveHealthTimeouts {
+		t.Fatalf("healthProbeOutcome fails = %d, want %d", newFails, maxConsecutiveHealthTimeouts)
+	}
+}
+
+func TestHealthProbeOutcomeSuccessResetsFailsAndReportsServing(t *testing.T) {
+	next, newFails, ok := healthProbeOutcome(maxConsecutiveHealthTimeouts-1, nil, healthpb.HealthCheckResponse_SERVING)
+	if !ok {
+		t.Fatal("healthProbeOutcome did not report ok for a successful probe")
+	}
+	if next != HealthServing {
+		t.Fatalf("healthProbeOutcome state = %v, want HealthServing", next)
+	}
+	if newFails != 0 {
+		t.Fatalf("healthProbeOutcome fails = %d, want 0 after a successful probe", newFails)
+	}
+}
+
+func TestHealthProbeOutcomeNotServingStatusResetsFails(t *testing.T) {
+	next, newFails, ok := healthProbeOutcome(maxConsecutiveHealthTimeouts-1, nil, healthpb.HealthCheckResponse_NOT_SERVING)
+	if !ok {
+		t.Fatal("healthProbeOutcome did not report ok for an answered probe")
+	}
+	if next != HealthNotServing {
+		t.Fatalf("healthProbeOutcome state = %v, want HealthNotServing", next)
+	}
+	if newFails != 0 {
+		t.Fatalf("healthProbeOutcome fails = %d, want 0 after an answered probe", newFails)
+	}
+}
+
+func TestHealthCheckerSubscribeReceivesTransitions(t *testing.T) {
+	hc := &HealthChecker{}
+	ch := hc.Subscribe()
+
+	hc.notify(HealthTransition{Node: "node-a", State: HealthNotServing})
+
+	select {
+	case got := <-ch:
+		if got.Node != "node-a" || got.State != HealthNotServing {
+			t.Fatalf("Subscribe channel received %+v, want {Node:node-a State:HealthNotServing}", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Subscribe channel did not receive the transition")
+	}
+}
+
+func TestHealthCheckerStateDefaultsToUnknown(t *testing.T) {
+	hc := &HealthChecker{}
+	if got := hc.State("never-probed"); got != HealthUnknown {
+		t.Fatalf("State() = %v, want HealthUnknown for a node that was never probed", got)
+	}
+}
@@ -0,0 +1,151 @@
+package interceptors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/relab/gorums"
+)
+
+func TestQuorumBroadcastRequiredAcks(t *testing.T) {
+	tests := []struct {
+		level AckLevel
+		total int
+		want  int
+	}{
+		{AckNone, 5, 0},
+		{AckOne, 5, 1},
+		{AckQuorum, 5, 3},
+		{AckQuorum, 4, 3},
+		{AckAll, 5, 5},
+	}
+	for _, tt := range tests {
+		q := &QuorumBroadcastInterceptor{quorumBroadcastConfig: quorumBroadcastConfig{ackLevel: tt.level}}
+		if got := q.requiredAcks(tt.total); got != tt.want {
+			t.Fatalf("requiredAcks(%d) at level %v = %d, want %d", tt.total, tt.level, got, tt.want)
+		}
+	}
+}
+
+// fakeQuorumSpec is a QuorumSpec whose Enough/Merge behavior is supplied by
+// the test, standing in for a generated quorum-call spec.
+type fakeQuorumSpec struct {
+	enough func(level AckLevel, total int, responses []*gorums.Message) bool
+	merge  func(responses []*gorums.Message) (*gorums.Message, error)
+}
+
+func (f fakeQuorumSpec) Enough(level AckLevel, total int, responses []*gorums.Message) bool {
+	return f.enough(level, total, responses)
+}
+
+func (f fakeQuorumSpec) Merge(responses []*gorums.Message) (*gorums.Message, error) {
+	return f.merge(responses)
+}
+
+func TestAcksSufficientWithoutQuorumSpecUsesRequiredCount(t *testing.T) {
+	responses := []*gorums.Message{{}, {}}
+	if acksSufficient(nil, AckQuorum, 5, 3, responses) {
+		t.Fatal("acksSufficient reported true with 2 of 3 required acks and no QuorumSpec")
+	}
+	if !acksSufficient(nil, AckQuorum, 5, 2, responses) {
+		t.Fatal("acksSufficient reported false once responses reached required, with no QuorumSpec")
+	}
+}
+
+func TestAcksSufficientQuorumSpecGovernsWhenSupplied(t *testing.T) {
+	// A QuorumSpec that is never satisfied must not be short-circuited by
+	// responses alone reaching the generic majority count - this is the
+	// bug the OR-condition this replaced would have let through.
+	neverEnough := fakeQuorumSpec{enough: func(AckLevel, int, []*gorums.Message) bool { return false }}
+	responses := []*gorums.Message{{}, {}, {}, {}, {}}
+	if acksSufficient(neverEnough, AckQuorum, 5, 3, responses) {
+		t.Fatal("acksSufficient reported true although the supplied QuorumSpec.Enough never does")
+	}
+
+	// Conversely, a QuorumSpec may report enough before the generic
+	// majority count would have.
+	alwaysEnough := fakeQuorumSpec{enough: func(AckLevel, int, []*gorums.Message) bool { return true }}
+	if !acksSufficient(alwaysEnough, AckQuorum, 5, 3, []*gorums.Message{{}}) {
+		t.Fatal("acksSufficient reported false although the supplied QuorumSpec.Enough was satisfied")
+	}
+}
+
+func TestOnInsufficientAcksDefaultPolicyReturnsBroadcastError(t *testing.T) {
+	q := &QuorumBroadcastInterceptor{}
+	local := &gorums.Message{}
+	nodeErrors := map[string]error{"node-a": errors.New("timeout")}
+
+	resp, err := q.onInsufficientAcks(local, nil, nodeErrors)
+	if resp != local {
+		t.Fatalf("onInsufficientAcks returned response %v, want the local response %v", resp, local)
+	}
+	var bErr *BroadcastError
+	if !errors.As(err, &bErr) {
+		t.Fatalf("onInsufficientAcks err = %v, want *BroadcastError", err)
+	}
+	if len(bErr.NodeErrors) != 1 {
+		t.Fatalf("BroadcastError.NodeErrors = %v, want 1 entry", bErr.NodeErrors)
+	}
+}
+
+func TestOnInsufficientAcksLastSuccessPolicy(t *testing.T) {
+	q := &QuorumBroadcastInterceptor{quorumBroadcastConfig: quorumBroadcastConfig{partialSuccess: PartialSuccessLastSuccess}}
+	local := &gorums.Message{}
+	last := &gorums.Message{}
+	responses := []*gorums.Message{{}, last}
+
+	resp, err := q.onInsufficientAcks(local, responses, nil)
+	if err != nil {
+		t.Fatalf("onInsufficientAcks err = %v, want nil when a successful response exists", err)
+	}
+	if resp != last {
+		t.Fatal("onInsufficientAcks did not return the most recent successful response")
+	}
+
+	// With no successful responses at all, it must fall back to the error
+	// policy rather than returning a nonexistent "last" response.
+	resp, err = q.onInsufficientAcks(local, nil, map[string]error{"node-a": errors.New("timeout")})
+	if resp != local {
+		t.Fatalf("onInsufficientAcks fallback returned %v, want local response %v", resp, local)
+	}
+	if err == nil {
+		t.Fatal("onInsufficientAcks fallback returned nil error with no successful responses")
+	}
+}
+
+func TestOnInsufficientAcksMergePolicy(t *testing.T) {
+	merged := &gorums.Message{}
+	quorum := fakeQuorumSpec{
+		merge: func(responses []*gorums.Message) (*gorums.Message, error) { return merged, nil },
+	}
+	q := &QuorumBroadcastInterceptor{
+		quorum:                quorum,
+		quorumBroadcastConfig: quorumBroadcastConfig{partialSuccess: PartialSuccessMerge},
+	}
+	local := &gorums.Message{}
+
+	resp, err := q.onInsufficientAcks(local, []*gorums.Message{{}}, nil)
+	if err != nil {
+		t.Fatalf("onInsufficientAcks err = %v, want nil when Merge succeeds", err)
+	}
+	if resp != merged {
+		t.Fatal("onInsufficientAcks did not return the QuorumSpec's merged response")
+	}
+
+	// A Merge failure must fall back to the error policy instead of
+	// propagating the merge error directly.
+	failingQuorum := fakeQuorumSpec{
+		merge: func(responses []*gorums.Message) (*gorums.Message, error) {
+			return nil, errors.New("merge failed")
+		},
+	}
+	q.quorum = failingQuorum
+	resp, err = q.onInsufficientAcks(local, []*gorums.Message{{}}, map[string]error{"node-a": errors.New("timeout")})
+	if resp != local {
+		t.Fatalf("onInsufficientAcks fallback returned %v, want local response %v", resp, local)
+	}
+	var bErr *BroadcastError
+	if !errors.As(err, &bErr) {
+		t.Fatalf("onInsufficientAcks fallback err = %v, want *BroadcastError", err)
+	}
+}
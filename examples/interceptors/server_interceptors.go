@@ -1,14 +1,25 @@
 package interceptors
 
 import (
+	"bufio"
+	"container/list"
 	"context"
-	"crypto/sha256"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"log"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/relab/gorums"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/protobuf/proto"
 )
@@ -85,24 +96,437 @@ func MetadataInterceptor(ctx gorums.ServerCtx, in *gorums.Message, next gorums.H
 	return out, err
 }
 
-// NewBroadcastInterceptor creates an interceptor that broadcasts incoming requests to all nodes
-// in the provided configuration. It uses message ID tracking to prevent broadcast loops -
-// each unique message (identified by its message ID) is only broadcasted once per server.
+// broadcastIDMetadataKey is the gRPC request metadata key (see
+// outgoingBroadcastContext) used to propagate a
+// broadcast's ID from the originating server to the nodes it broadcasts to,
+// so they can recognize the message as an already-tracked broadcast rather
+// than starting a new one of their own.
+const broadcastIDMetadataKey = "broadcastID"
+
+// broadcastShardCount is the number of shards the broadcast registry splits
+// its entries across. Sharding by a hash of the broadcast ID means unrelated
+// broadcasts almost never contend on the same mutex.
+const broadcastShardCount = 32
+
+// defaultBroadcastTTL bounds how long a registry entry is kept around when
+// a broadcast never completes (e.g. a node that never replies), so entries
+// are evicted on a timer instead of accumulating forever.
+const defaultBroadcastTTL = 5 * time.Minute
+
+// broadcastEntry tracks one broadcast: the cancel function that stops
+// dispatch and tells already-contacted nodes to abort, which nodes have
+// finished, and when the entry should next be evicted.
 //
-// Parameters:
-//   - cfg: Configuration containing the nodes to broadcast to
-//   - method: The RPC method name to invoke on other nodes (e.g., "proto.Storage.WriteRPC")
+// expires serves two purposes depending on terminal: while the broadcast is
+// still active, it's when evictExpired should time the entry out; once
+// terminal is set, it's been repurposed as the deadline for reaping the
+// record entirely. The entry is kept around, terminal but not deleted, for
+// one more TTL after it completes or times out so a WaitBroadcast call that
+// arrives after the fact - the common case, since dispatch to a handful of
+// local nodes usually finishes before a caller gets around to waiting on it
+// - still gets a real answer instead of being told the ID is unknown.
+type broadcastEntry struct {
+	cancel  context.CancelFunc
+	done    chan struct{}
+	expires time.Time
+
+	mu       sync.Mutex
+	nodes    []string
+	finished map[string]struct{}
+	// skipped records nodes that were never actually dispatched to
+	// because HealthChecker reported them NotServing at dispatch time, but
+	// which still count toward finished so the broadcast can complete. It
+	// lets wait tell a caller that a leg was skipped rather than
+	// delivered - see BroadcastSkippedError.
+	skipped map[string]struct{}
+	// terminal is set once the entry has completed or timed out, i.e.
+	// once cancel has been called and done closed. It guards against
+	// evict/evictExpired acting twice on the same entry.
+	terminal bool
+	// timedOut is set when the entry became terminal via evictExpired
+	// rather than by every node reporting done, so wait can tell a
+	// broadcast that actually finished from one that merely ran out the
+	// TTL with some nodes never having reported in.
+	timedOut bool
+}
+
+// pendingLocked returns the nodes that have not yet reported completion.
+// Callers must hold entry.mu.
+func (e *broadcastEntry) pendingLocked() []string {
+	var pending []string
+	for _, n := range e.nodes {
+		if _, ok := e.finished[n]; !ok {
+			pending = append(pending, n)
+		}
+	}
+	return pending
+}
+
+// skippedLocked returns the nodes that were marked done via markNodeSkipped
+// rather than markNodeDone, i.e. ones never actually dispatched to. Callers
+// must hold entry.mu.
+func (e *broadcastEntry) skippedLocked() []string {
+	var skipped []string
+	for _, n := range e.nodes {
+		if _, ok := e.skipped[n]; ok {
+			skipped = append(skipped, n)
+		}
+	}
+	return skipped
+}
+
+type broadcastShard struct {
+	mu      sync.Mutex
+	entries map[string]*broadcastEntry
+}
+
+// broadcastRegistry tracks in-flight and recently-finished broadcasts by ID
+// so they can be cancelled or waited on from outside the dispatching
+// goroutine. It shards entries by a hash of the ID to reduce mutex
+// contention, and retires entries on completion or TTL expiry by keeping
+// them around terminal for one more TTL - so a post-hoc Wait still works -
+// before reaping them, instead of clearing the whole table at a size
+// threshold.
+type broadcastRegistry struct {
+	shards [broadcastShardCount]*broadcastShard
+	ttl    time.Duration
+}
+
+func newBroadcastRegistry(ttl time.Duration) *broadcastRegistry {
+	r := &broadcastRegistry{ttl: ttl}
+	for i := range r.shards {
+		r.shards[i] = &broadcastShard{entries: make(map[string]*broadcastEntry)}
+	}
+	return r
+}
+
+func (r *broadcastRegistry) shardFor(id string) *broadcastShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(id))
+	return r.shards[h.Sum32()%broadcastShardCount]
+}
+
+// register starts tracking a new broadcast to the given nodes and returns a
+// context that is cancelled when the caller cancels the broadcast via
+// CancelBroadcast.
+func (r *broadcastRegistry) register(id string, nodes []string) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	entry := &broadcastEntry{
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		expires:  time.Now().Add(r.ttl),
+		nodes:    nodes,
+		finished: make(map[string]struct{}),
+	}
+	shard := r.shardFor(id)
+	shard.mu.Lock()
+	shard.entries[id] = entry
+	shard.mu.Unlock()
+	return ctx
+}
+
+// markNodeDone records that node has finished its leg of the broadcast
+// identified by id, and evicts the entry once every one of total nodes has
+// reported in.
+func (r *broadcastRegistry) markNodeDone(id, node string, total int) {
+	shard := r.shardFor(id)
+	shard.mu.Lock()
+	entry, ok := shard.entries[id]
+	shard.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	entry.mu.Lock()
+	entry.finished[node] = struct{}{}
+	complete := len(entry.finished) >= total
+	entry.mu.Unlock()
+
+	if complete {
+		r.evict(id)
+	}
+}
+
+// markNodeSkipped records that node's leg of the broadcast identified by id
+// was never dispatched - typically because HealthChecker reported it
+// NotServing - but should still count toward completion, the same way
+// markNodeDone's node would. A wait call that completes successfully but
+// finds skipped nodes returns a *BroadcastSkippedError instead of nil, so a
+// caller can distinguish "every node actually replicated" from "no legs are
+// still outstanding".
+func (r *broadcastRegistry) markNodeSkipped(id, node string, total int) {
+	shard := r.shardFor(id)
+	shard.mu.Lock()
+	entry, ok := shard.entries[id]
+	shard.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	entry.mu.Lock()
+	if entry.skipped == nil {
+		entry.skipped = make(map[string]struct{})
+	}
+	entry.skipped[node] = struct{}{}
+	entry.finished[node] = struct{}{}
+	complete := len(entry.finished) >= total
+	entry.mu.Unlock()
+
+	if complete {
+		r.evict(id)
+	}
+}
+
+// evict marks the entry for id (if present and not already terminal) as
+// complete, cancels its context, and signals any waiters. The entry itself
+// stays in the shard - see broadcastEntry - so it is reaped, not deleted,
+// by a later evictExpired pass.
+func (r *broadcastRegistry) evict(id string) {
+	shard := r.shardFor(id)
+	shard.mu.Lock()
+	entry, ok := shard.entries[id]
+	shard.mu.Unlock()
+	if !ok {
+		return
+	}
+	if !markTerminal(entry, r.ttl, false) {
+		return
+	}
+	entry.cancel()
+	close(entry.done)
+}
+
+// markTerminal marks entry terminal and due for reaping after ttl, setting
+// timedOut if requested. It reports whether this call was the one that made
+// the entry terminal, so the caller knows whether it's responsible for
+// cancelling the context and closing done - calling either twice on the
+// same entry would panic.
+func markTerminal(entry *broadcastEntry, ttl time.Duration, timedOut bool) bool {
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.terminal {
+		return false
+	}
+	entry.terminal = true
+	entry.timedOut = timedOut
+	entry.expires = time.Now().Add(ttl)
+	return true
+}
+
+// evictExpired does two things, walking every shard once: it times out
+// entries whose TTL has passed without completing (marking them terminal so
+// a waiter gets a *BroadcastTimeoutError instead of nil), and it reaps
+// entries that have already been terminal for one more TTL, finally
+// deleting them from the shard. It's called periodically rather than on
+// every registration/eviction so a single slow broadcast can't delay
+// eviction of unrelated ones.
+func (r *broadcastRegistry) evictExpired() {
+	now := time.Now()
+	for _, shard := range r.shards {
+		shard.mu.Lock()
+		for id, entry := range shard.entries {
+			entry.mu.Lock()
+			expired := now.After(entry.expires)
+			terminal := entry.terminal
+			entry.mu.Unlock()
+			if !expired {
+				continue
+			}
+			if terminal {
+				delete(shard.entries, id)
+				continue
+			}
+			if markTerminal(entry, r.ttl, true) {
+				entry.cancel()
+				close(entry.done)
+			}
+		}
+		shard.mu.Unlock()
+	}
+}
+
+// cancel stops dispatch for the broadcast identified by id and reports
+// whether a matching, still-active in-flight broadcast was found.
+func (r *broadcastRegistry) cancel(id string) bool {
+	shard := r.shardFor(id)
+	shard.mu.Lock()
+	entry, ok := shard.entries[id]
+	shard.mu.Unlock()
+	if !ok {
+		return false
+	}
+	entry.mu.Lock()
+	terminal := entry.terminal
+	entry.mu.Unlock()
+	if terminal {
+		return false
+	}
+	entry.cancel()
+	return true
+}
+
+// wait blocks until the broadcast identified by id finishes or ctx is done.
+// It still finds id after the broadcast has completed or timed out: evict
+// and evictExpired keep a terminal record around for a while rather than
+// deleting it outright, specifically so a Wait call that arrives after
+// eviction - the common case - gets a real answer instead of the
+// not-found error below. If the entry was evicted by its TTL rather than
+// every node reporting completion, it returns a *BroadcastTimeoutError
+// instead of nil, so a merely-timed-out broadcast isn't mistaken for one
+// that actually finished. If every node reported in but one or more of them
+// did so via markNodeSkipped rather than markNodeDone, it returns a
+// *BroadcastSkippedError instead of nil, so "no legs are still outstanding"
+// isn't mistaken for "every node actually received the broadcast".
+func (r *broadcastRegistry) wait(ctx context.Context, id string) error {
+	shard := r.shardFor(id)
+	shard.mu.Lock()
+	entry, ok := shard.entries[id]
+	shard.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("broadcast: no in-flight broadcast with id %q", id)
+	}
+	select {
+	case <-entry.done:
+		entry.mu.Lock()
+		timedOut := entry.timedOut
+		pending := entry.pendingLocked()
+		skipped := entry.skippedLocked()
+		entry.mu.Unlock()
+		if timedOut {
+			return &BroadcastTimeoutError{Pending: pending}
+		}
+		if len(skipped) > 0 {
+			return &BroadcastSkippedError{Skipped: skipped}
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// BroadcastInterceptor broadcasts incoming requests for a given method to all
+// nodes in a configuration, and keeps a registry of the broadcasts it starts
+// so a caller can cancel one in flight or wait for it to finish.
 //
-// The interceptor will:
-//  1. Check if this message ID has already been broadcasted (loop prevention)
-//  2. Process the request locally by calling next()
-//  3. Broadcast the request to all nodes in the configuration (fire-and-forget)
-//  4. Return the local response
+// Unlike a plain gorums.Interceptor func, BroadcastInterceptor is a value
+// because CancelBroadcast and WaitBroadcast need to reach the same registry
+// that Intercept populates.
+type BroadcastInterceptor struct {
+	cfg      gorums.Configuration
+	method   string
+	registry *broadcastRegistry
+
+	broadcastConfig
+	retryMu     sync.Mutex
+	retryQueues map[string]*nodeRetryQueue
+
+	health *HealthChecker
+}
+
+// BroadcastOption configures a BroadcastInterceptor created by
+// NewBroadcastInterceptor.
+type BroadcastOption func(*broadcastConfig)
+
+type broadcastConfig struct {
+	backoff        BackoffStrategy
+	maxRetries     int
+	retryQueueSize int
+
+	healthInterval time.Duration
+	healthService  string
+
+	dedup    DedupStore
+	dedupTTL time.Duration
+}
+
+// defaultDedupTTL bounds how long a dispatched broadcast's ID is remembered
+// by the default dedup store, long enough to cover a client retrying the
+// same request after a crash-restart but without holding every ID forever.
+const defaultDedupTTL = 10 * time.Minute
+
+func defaultBroadcastConfig() broadcastConfig {
+	return broadcastConfig{
+		backoff:        ExponentialBackoff{Config: DefaultBackoffConfig},
+		maxRetries:     5,
+		retryQueueSize: 256,
+		dedup:          NewMemDedupStore(),
+		dedupTTL:       defaultDedupTTL,
+	}
+}
+
+// WithBroadcastBackoff sets the strategy used to space out retries of failed
+// broadcast legs. The default is ExponentialBackoff with DefaultBackoffConfig.
+func WithBroadcastBackoff(strategy BackoffStrategy) BroadcastOption {
+	return func(c *broadcastConfig) { c.backoff = strategy }
+}
+
+// WithBroadcastMaxRetries caps how many times a failed broadcast leg to a
+// node is retried before it is given up on. The default is 5.
+func WithBroadcastMaxRetries(n int) BroadcastOption {
+	return func(c *broadcastConfig) { c.maxRetries = n }
+}
+
+// WithHealthCheck makes the BroadcastInterceptor consult a background
+// HealthChecker before dispatching to each node, skipping nodes whose latest
+// probe of service (via grpc.health.v1.Health/Check) reports NOT_SERVING.
+// By default no health checking is performed and all configured nodes are
+// always dispatched to.
+func WithHealthCheck(interval time.Duration, service string) BroadcastOption {
+	return func(c *broadcastConfig) {
+		c.healthInterval = interval
+		c.healthService = service
+	}
+}
+
+// WithDedupStore makes a BroadcastInterceptor consult store before
+// originating a broadcast and mark it once dispatch begins, so that if store
+// survives a process restart (unlike the default MemDedupStore), a restarted
+// server won't re-broadcast a message it already propagated before it
+// crashed. The key checked and marked is a hash of the message's content
+// (see dedupKeyFor), not the broadcast's ID, so byte-identical requests from
+// different clients are deduplicated against each other.
+func WithDedupStore(store DedupStore) BroadcastOption {
+	return func(c *broadcastConfig) { c.dedup = store }
+}
+
+// WithDedupTTL sets how long a dispatched broadcast's ID is remembered by
+// the dedup store. The default is 10 minutes.
+func WithDedupTTL(ttl time.Duration) BroadcastOption {
+	return func(c *broadcastConfig) { c.dedupTTL = ttl }
+}
+
+// NewBroadcastInterceptor creates a BroadcastInterceptor that broadcasts
+// incoming requests for method to all nodes in cfg. Register its Intercept
+// method with gorums.WithInterceptors; keep the returned value around to call
+// CancelBroadcast or WaitBroadcast.
 //
 // Loop Prevention:
-// When a client sends a write request, it gets a unique message ID. When server 0 receives
-// it and broadcasts to servers 1, 2, 3, they all receive the SAME message ID. Each server
-// tracks which message IDs it has already broadcast, preventing infinite loops.
+// When a client sends a write request, it gets a unique message ID. When
+// server 0 receives it and broadcasts to servers 1, 2, 3, the broadcast ID
+// derived from that message ID (see broadcastID) travels with it via a
+// "broadcastID" metadata entry. A server that receives a message already
+// carrying that entry knows it is a forwarded leg of someone else's
+// broadcast, processes it locally, and does not broadcast it again.
+//
+// Failed legs are retried in the background with backoff, rather than
+// dropped: see WithBroadcastBackoff and WithBroadcastMaxRetries.
+//
+// Crash Recovery:
+// Metadata-based loop prevention only covers a single broadcast's lifetime.
+// If this server crashes after dispatching but before the client gives up
+// and retries, the retry looks like a brand new broadcast - and it arrives
+// with a new message ID, since that's a per-RPCCall sequence number, not a
+// property of the logical request, so it cannot be what tells the retry
+// apart from a fresh one. A DedupStore guards against that instead: before
+// dispatching, the interceptor checks whether a hash of the message's content
+// has already been marked as broadcast, and skips dispatch if so. Because
+// the key is content-derived rather than the per-call broadcast ID, two
+// different clients broadcasting byte-identical requests are deduplicated
+// against each other - a trade-off against Loop Prevention's broadcastID,
+// which must NOT do that (see broadcastID). The default MemDedupStore
+// doesn't survive a restart; pass a WithDedupStore backed by a
+// FileDedupStore (or your own implementation) to make that guarantee
+// durable.
 //
 // Example usage:
 //
@@ -111,87 +535,842 @@ func MetadataInterceptor(ctx gorums.ServerCtx, in *gorums.Message, next gorums.H
 //	clientCfg, _ := proto.NewConfiguration(clientMgr, gorums.WithNodeList(otherNodeAddresses))
 //
 //	// Create server with broadcast interceptor
-//	srv := gorums.NewServer(gorums.WithInterceptors(
-//	    interceptors.NewBroadcastInterceptor(clientCfg, "proto.Storage.WriteRPC"),
-//	))
-func NewBroadcastInterceptor(cfg gorums.Configuration, method string) gorums.Interceptor {
-	// Cache of message content hashes we've already broadcast (to prevent loops)
-	// We use content hash instead of message seq number because each RPCCall creates a new seq number
-	var mu sync.Mutex
-	broadcastedHashes := make(map[string]struct{})
+//	bcast := interceptors.NewBroadcastInterceptor(clientCfg, "proto.Storage.WriteRPC")
+//	srv := gorums.NewServer(gorums.WithInterceptors(bcast.Intercept))
+//
+//	// Later, an operator or the originating client can cancel it:
+//	bcast.CancelBroadcast(id)
+func NewBroadcastInterceptor(cfg gorums.Configuration, method string, opts ...BroadcastOption) *BroadcastInterceptor {
+	c := defaultBroadcastConfig()
+	for _, opt := range opts {
+		opt(&c)
+	}
+	b := &BroadcastInterceptor{
+		cfg:             cfg,
+		method:          method,
+		registry:        newBroadcastRegistry(defaultBroadcastTTL),
+		broadcastConfig: c,
+		retryQueues:     make(map[string]*nodeRetryQueue),
+	}
+	if c.healthInterval > 0 {
+		b.health = NewHealthChecker(cfg, c.healthService, c.healthInterval)
+	}
+	go b.evictExpiredLoop()
+	return b
+}
 
-	return func(ctx gorums.ServerCtx, msg *gorums.Message, next gorums.Handler) (*gorums.Message, error) {
-		// Only broadcast for the specified method
-		if msg.GetMethod() != method {
-			return next(ctx, msg)
-		}
+func (b *BroadcastInterceptor) evictExpiredLoop() {
+	ticker := time.NewTicker(b.registry.ttl / 5)
+	defer ticker.Stop()
+	for range ticker.C {
+		b.registry.evictExpired()
+	}
+}
 
-		// Create a unique hash from the message content
-		// This ensures we detect duplicates even when sequence numbers differ
-		msgBytes, err := proto.Marshal(msg.GetProtoMessage())
-		if err != nil {
-			log.Printf("BroadcastInterceptor: Failed to marshal message: %v", err)
-			return next(ctx, msg)
+// outgoingBroadcastContext attaches id as gRPC request metadata under
+// broadcastIDMetadataKey, so it actually rides the wire with the RPC rather
+// than only living on the local *gorums.Message wrapper, which RPCCall never
+// sends (it only transmits the cloned proto.Message payload).
+func outgoingBroadcastContext(ctx context.Context, id string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, broadcastIDMetadataKey, id)
+}
+
+// forwardedBroadcastID returns the broadcast ID carried on an incoming
+// request, and whether one was found. It checks gRPC request metadata first,
+// since that's how outgoingBroadcastContext propagates the ID across the
+// wire to another node (RegisterBroadcastCancelRPC's handler finds it there
+// too, since sendCancelRPC uses outgoingBroadcastContext); msg's own metadata
+// entries are checked as a fallback for callers that attach it locally. A message
+// carrying this ID is a leg forwarded by another server's
+// BroadcastInterceptor, not a fresh request.
+func forwardedBroadcastID(ctx context.Context, msg *gorums.Message) (string, bool) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get(broadcastIDMetadataKey); len(vals) > 0 {
+			return vals[0], true
 		}
-		hash := sha256.Sum256(msgBytes)
-		hashStr := fmt.Sprintf("%x", hash[:8]) // Use first 8 bytes for efficiency
-
-		// Check if we've already broadcast this exact message content
-		mu.Lock()
-		_, alreadyBroadcasted := broadcastedHashes[hashStr]
-		if !alreadyBroadcasted {
-			broadcastedHashes[hashStr] = struct{}{}
-			// Limit cache size to prevent memory leak
-			if len(broadcastedHashes) > 10000 {
-				// Clear half the cache
-				count := 0
-				for h := range broadcastedHashes {
-					delete(broadcastedHashes, h)
-					count++
-					if count >= 5000 {
-						break
-					}
-				}
+	}
+	for _, e := range msg.GetEntry() {
+		if e.GetKey() == broadcastIDMetadataKey {
+			return e.GetValue(), true
+		}
+	}
+	return "", false
+}
+
+// broadcastID derives the ID an originating server assigns to a broadcast
+// from the incoming message's own ID, not its content: two different clients
+// broadcasting byte-identical requests get distinct message IDs and so are
+// not falsely deduplicated against each other, which a content hash would
+// do. It identifies one broadcast's dispatch - for the registry (cancel,
+// wait) and for Loop Prevention's forwarded-leg detection - not the
+// logical request behind it: msg.GetMessageID() is a per-RPCCall sequence
+// number, so it changes on every retry of the same logical request,
+// including after this server crashes and the client retries. That's why
+// DedupStore uses a different, content-derived key (see dedupKeyFor) rather
+// than this one - see the Crash Recovery section on NewBroadcastInterceptor.
+func broadcastID(msg *gorums.Message) string {
+	return fmt.Sprintf("%d", msg.GetMessageID())
+}
+
+// dedupKeyFor derives the key a DedupStore uses to recognize a message it
+// has already dispatched, from the message's marshaled content rather than
+// broadcastID's per-call message ID. Unlike broadcastID, this key must
+// survive the client retrying the same logical request after this server
+// crashes and restarts - and a retry gets a new message ID, so only a
+// content-derived key can still recognize it as the same request. The
+// trade-off, same as the one the registry ID exists to avoid, is that two
+// different clients broadcasting byte-identical requests collide in the
+// DedupStore and the second is (incorrectly) treated as already dispatched;
+// see the Crash Recovery section on NewBroadcastInterceptor.
+func dedupKeyFor(msg *gorums.Message) string {
+	b, _ := proto.Marshal(msg.GetProtoMessage())
+	return dedupKeyForContent(b)
+}
+
+// dedupKeyForContent is the hashing step of dedupKeyFor, split out so it -
+// and the crash/retry scenario it exists to survive - can be exercised
+// without constructing a real *gorums.Message.
+func dedupKeyForContent(content []byte) string {
+	h := fnv.New64a()
+	_, _ = h.Write(content)
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+// Intercept implements gorums.Interceptor. Register it via
+// gorums.WithInterceptors(b.Intercept).
+func (b *BroadcastInterceptor) Intercept(ctx gorums.ServerCtx, msg *gorums.Message, next gorums.Handler) (*gorums.Message, error) {
+	// Only broadcast for the specified method
+	if msg.GetMethod() != b.method {
+		return next(ctx, msg)
+	}
+
+	if id, forwarded := forwardedBroadcastID(ctx, msg); forwarded {
+		// This is a leg of a broadcast started elsewhere: process locally
+		// only. Nothing is reported back from here - the originating
+		// registry entry lives on another server, and that server's own
+		// dispatch goroutine tracks this leg's completion - so we just
+		// avoid re-broadcasting.
+		_ = id
+		return next(ctx, msg)
+	}
+
+	// This server originates the broadcast: derive a per-call registry ID
+	// from the incoming message ID (not its content) so two different
+	// clients writing the same key aren't falsely deduplicated against
+	// each other in the registry or over the wire (see broadcastID). The
+	// DedupStore check below uses a separate, content-derived key instead,
+	// since it must still recognize the message if this server crashes and
+	// the client retries with a new message ID (see dedupKeyFor).
+	id := broadcastID(msg)
+	dedupKey := dedupKeyFor(msg)
+
+	resp, err := next(ctx, msg)
+
+	if b.dedup.Seen(dedupKey) {
+		// Already dispatched before an earlier crash; don't do it again.
+		return resp, err
+	}
+	b.dedup.Mark(dedupKey, b.dedupTTL)
+
+	nodes := b.cfg.Nodes()
+	addrs := make([]string, len(nodes))
+	for i, node := range nodes {
+		addrs[i] = node.Address()
+	}
+	broadcastCtx := b.registry.register(id, addrs)
+
+	msgCopy := proto.Clone(msg.GetProtoMessage())
+
+	go func() {
+		var wg sync.WaitGroup
+		for _, node := range nodes {
+			if b.health != nil && b.health.State(node.Address()) == HealthNotServing {
+				// Skip nodes the health checker has already marked down
+				// instead of waiting out a dial timeout against them. The
+				// node never actually received the broadcast, so record it
+				// with markNodeSkipped rather than markNodeDone: a
+				// subsequent WaitBroadcast still returns once every other
+				// leg finishes, but via *BroadcastSkippedError rather than
+				// nil, so a caller can tell this leg was never delivered.
+				b.registry.markNodeSkipped(id, node.Address(), len(nodes))
+				continue
 			}
+			wg.Add(1)
+			go func(n *gorums.Node) {
+				defer wg.Done()
+
+				nodeCtx := outgoingBroadcastContext(n.Context(broadcastCtx), id)
+				_, callErr := gorums.RPCCall(nodeCtx, msgCopy, b.method)
+				if callErr == nil {
+					b.registry.markNodeDone(id, n.Address(), len(nodes))
+					return
+				}
+				if broadcastCtx.Err() != nil {
+					// We were cancelled mid-flight: ask the node to abort
+					// whatever downstream work our RPC may have triggered,
+					// and consider the leg finished - there's nothing left
+					// to wait for or retry.
+					b.sendCancelRPC(n, id, msgCopy)
+					b.registry.markNodeDone(id, n.Address(), len(nodes))
+					return
+				}
+				// Transient failure: hand the leg off to the node's retry
+				// worker instead of dropping it silently. The leg is only
+				// truly finished once the worker succeeds, gives up after
+				// maxRetries, or the broadcast is cancelled - see
+				// runRetryWorker, which reports completion itself.
+				b.enqueueRetry(broadcastCtx, id, n, msgCopy, len(nodes))
+			}(node)
 		}
-		mu.Unlock()
+		wg.Wait()
+	}()
 
-		if alreadyBroadcasted {
-			// Silently skip re-broadcast
-			return next(ctx, msg)
+	return resp, err
+}
+
+// CancelBroadcast stops dispatching the broadcast identified by id to any
+// node it hasn't already reached, and sends the secondary cancel RPC to each
+// node whose leg was still in flight or awaiting retry, so it can abort its
+// own downstream work. Nodes whose leg had already completed successfully
+// are not sent the cancel RPC. It reports whether a matching in-flight
+// broadcast was found.
+func (b *BroadcastInterceptor) CancelBroadcast(id string) bool {
+	return b.registry.cancel(id)
+}
+
+// WaitBroadcast blocks until every node has either completed or been
+// skipped for its leg of the broadcast identified by id, or until ctx is
+// done. A nil return means no leg is still outstanding, not that every node
+// actually received the broadcast: a node the HealthChecker had already
+// marked down is skipped rather than dispatched to, and WaitBroadcast
+// reports that with a *BroadcastSkippedError rather than nil. If the
+// broadcast's TTL expires first, it returns a *BroadcastTimeoutError
+// instead, so a caller can't mistake a timed-out broadcast for one where
+// every leg was accounted for.
+func (b *BroadcastInterceptor) WaitBroadcast(ctx context.Context, id string) error {
+	return b.registry.wait(ctx, id)
+}
+
+// BroadcastTimeoutError is returned by WaitBroadcast when the broadcast's
+// registry entry was evicted by its TTL before every node reported
+// completion.
+type BroadcastTimeoutError struct {
+	// Pending lists the nodes that had not reported completion when the
+	// entry was evicted.
+	Pending []string
+}
+
+func (e *BroadcastTimeoutError) Error() string {
+	return fmt.Sprintf("broadcast: timed out waiting for %d node(s): %v", len(e.Pending), e.Pending)
+}
+
+// BroadcastSkippedError is returned by WaitBroadcast when every node
+// accounted for its leg of the broadcast, but one or more of them were
+// skipped - never actually dispatched to - rather than completed, typically
+// because HealthChecker had already marked the node NotServing. Unlike
+// BroadcastTimeoutError, this does not mean the broadcast is still in
+// flight: nothing further will happen for the skipped nodes until the next
+// broadcast or an out-of-band repair (e.g. an anti-entropy pass) reaches
+// them.
+type BroadcastSkippedError struct {
+	// Skipped lists the nodes that were never dispatched to.
+	Skipped []string
+}
+
+func (e *BroadcastSkippedError) Error() string {
+	return fmt.Sprintf("broadcast: %d node(s) were skipped rather than dispatched to: %v", len(e.Skipped), e.Skipped)
+}
+
+// DroppedRetries reports how many queued retries for node have been dropped
+// because its retry queue was full, for callers that want to monitor a
+// persistently unreachable node.
+func (b *BroadcastInterceptor) DroppedRetries(node string) uint64 {
+	b.retryMu.Lock()
+	q, ok := b.retryQueues[node]
+	b.retryMu.Unlock()
+	if !ok {
+		return 0
+	}
+	return q.droppedCount()
+}
+
+// enqueueRetry schedules msg for retry against n, starting that node's retry
+// worker the first time it is needed. Unlike the first attempt in Intercept,
+// the leg this item represents is not yet done - runRetryWorker is
+// responsible for reporting it to b.registry via markNodeDone, exactly once,
+// once it reaches a terminal outcome. An item that push drops because the
+// node's queue is full is never reported, so its leg stays pending until the
+// registry entry times out.
+func (b *BroadcastInterceptor) enqueueRetry(ctx context.Context, id string, n *gorums.Node, msg proto.Message, total int) {
+	b.retryMu.Lock()
+	q, ok := b.retryQueues[n.Address()]
+	if !ok {
+		q = newNodeRetryQueue(b.retryQueueSize)
+		b.retryQueues[n.Address()] = q
+		go b.runRetryWorker(n, q)
+	}
+	b.retryMu.Unlock()
+	q.push(retryItem{ctx: ctx, id: id, msg: msg, total: total})
+}
+
+// runRetryWorker drains q forever, waiting out b's backoff strategy between
+// attempts and respecting each item's own broadcast context so a cancelled
+// broadcast's retries are dropped rather than retried. It marks the item's
+// leg done in b.registry exactly once, when it reaches a terminal outcome:
+// success, the broadcast being cancelled, or retries being exhausted - never
+// merely because it was queued, so CancelBroadcast and WaitBroadcast stay
+// accurate while a retry is outstanding.
+func (b *BroadcastInterceptor) runRetryWorker(n *gorums.Node, q *nodeRetryQueue) {
+	for {
+		item, ok := q.pop()
+		if !ok {
+			return
+		}
+		if item.ctx.Err() != nil {
+			// The broadcast was cancelled before this leg could be
+			// retried. n was already contacted once (that's why this leg
+			// exists), so it still needs the same secondary cancel call
+			// the first-attempt path in Intercept sends.
+			b.sendCancelRPC(n, item.id, item.msg)
+			b.registry.markNodeDone(item.id, n.Address(), item.total)
+			continue
+		}
+		if item.retries >= b.maxRetries {
+			b.registry.markNodeDone(item.id, n.Address(), item.total)
+			continue
 		}
 
-		// Process locally first
-		resp, err := next(ctx, msg)
+		delay := b.backoff.Delay(item.retries, item.prevDelay)
+		select {
+		case <-time.After(delay):
+		case <-item.ctx.Done():
+			// Cancelled while waiting out the backoff delay.
+			b.sendCancelRPC(n, item.id, item.msg)
+			b.registry.markNodeDone(item.id, n.Address(), item.total)
+			continue
+		}
 
-		// Broadcast to all nodes asynchronously (fire-and-forget)
-		go func() {
-			broadcastCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
+		nodeCtx := outgoingBroadcastContext(n.Context(item.ctx), item.id)
+		_, err := gorums.RPCCall(nodeCtx, item.msg, b.method)
+		if err == nil {
+			b.registry.markNodeDone(item.id, n.Address(), item.total)
+			continue
+		}
+		if item.ctx.Err() != nil {
+			// Cancelled mid-flight, same condition the first-attempt path
+			// in Intercept handles: ask n to abort whatever downstream
+			// work its already-sent RPC may have triggered.
+			b.sendCancelRPC(n, item.id, item.msg)
+			b.registry.markNodeDone(item.id, n.Address(), item.total)
+			continue
+		}
+		item.retries++
+		item.prevDelay = delay
+		q.push(item)
+	}
+}
 
-			// Clone the message to avoid data races
-			msgCopy := proto.Clone(msg.GetProtoMessage())
+// sendCancelRPC calls method+"Cancel" on n, the same secondary RPC the
+// first-attempt dispatch in Intercept sends, so a node that already received
+// a broadcast leg which is now in backoff/retry still hears about a
+// cancellation instead of only finding out (or not) on its next retry.
+func (b *BroadcastInterceptor) sendCancelRPC(n *gorums.Node, id string, msg proto.Message) {
+	cancelCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, _ = gorums.RPCCall(outgoingBroadcastContext(n.Context(cancelCtx), id), msg, b.method+"Cancel")
+}
 
-			// Broadcast to all nodes
-			var wg sync.WaitGroup
-			for _, node := range cfg.Nodes() {
-				wg.Add(1)
-				go func(n *gorums.Node) {
-					defer wg.Done()
-					nodeCtx := n.Context(broadcastCtx)
+// retryItem is a single failed broadcast leg awaiting retry.
+type retryItem struct {
+	ctx       context.Context
+	id        string
+	msg       proto.Message
+	retries   int
+	prevDelay time.Duration
+	total     int
+}
 
-					// Send the message using gorums.RPCCall
-					_, _ = gorums.RPCCall(nodeCtx, msgCopy, method)
-					// Silently ignore errors
-				}(node)
+// nodeRetryQueue is a bounded, FIFO queue of retries destined for one node.
+// Pushing past maxSize drops the oldest pending retry and increments
+// dropped, rather than growing without bound when a node stays unreachable.
+type nodeRetryQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	items   []retryItem
+	maxSize int
+	dropped uint64
+}
+
+func newNodeRetryQueue(maxSize int) *nodeRetryQueue {
+	q := &nodeRetryQueue{maxSize: maxSize}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *nodeRetryQueue) push(item retryItem) {
+	q.mu.Lock()
+	if len(q.items) >= q.maxSize {
+		q.items = q.items[1:]
+		atomic.AddUint64(&q.dropped, 1)
+	}
+	q.items = append(q.items, item)
+	q.mu.Unlock()
+	q.cond.Signal()
+}
+
+// pop blocks until an item is available. It never returns ok=false; it
+// exists so runRetryWorker has a clean exit point if nodeRetryQueue grows a
+// Close method in the future.
+func (q *nodeRetryQueue) pop() (retryItem, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) == 0 {
+		q.cond.Wait()
+	}
+	item := q.items[0]
+	q.items = q.items[1:]
+	return item, true
+}
+
+func (q *nodeRetryQueue) droppedCount() uint64 {
+	return atomic.LoadUint64(&q.dropped)
+}
+
+// BackoffStrategy computes how long to wait before retrying a failed
+// broadcast leg, given the number of consecutive failures so far for that
+// leg and the delay used for the previous attempt (0 on the first retry).
+// Implementations must be safe for concurrent use.
+type BackoffStrategy interface {
+	Delay(retries int, prevDelay time.Duration) time.Duration
+}
+
+// BackoffConfig parameterizes ExponentialBackoff. The defaults in
+// DefaultBackoffConfig match gRPC's default connection-backoff policy.
+type BackoffConfig struct {
+	BaseDelay time.Duration
+	Factor    float64
+	Jitter    float64
+	MaxDelay  time.Duration
+}
+
+// DefaultBackoffConfig mirrors gRPC's default connection-backoff policy.
+var DefaultBackoffConfig = BackoffConfig{
+	BaseDelay: 1 * time.Second,
+	Factor:    1.6,
+	Jitter:    0.2,
+	MaxDelay:  120 * time.Second,
+}
+
+// ExponentialBackoff computes BaseDelay*Factor^retries, capped at MaxDelay
+// and randomized by +/-Jitter, matching gRPC's default backoff policy.
+type ExponentialBackoff struct {
+	Config BackoffConfig
+}
+
+func (b ExponentialBackoff) Delay(retries int, _ time.Duration) time.Duration {
+	delay := float64(b.Config.BaseDelay) * math.Pow(b.Config.Factor, float64(retries))
+	if max := float64(b.Config.MaxDelay); delay > max {
+		delay = max
+	}
+	delay += (rand.Float64()*2 - 1) * b.Config.Jitter * delay
+	return time.Duration(delay)
+}
+
+// ConstantBackoff always waits the same Delay between retries.
+type ConstantBackoff struct {
+	Delay_ time.Duration
+}
+
+func (b ConstantBackoff) Delay(int, time.Duration) time.Duration {
+	return b.Delay_
+}
+
+// LinearBackoff waits BaseDelay+Increment*retries between retries, capped at
+// MaxDelay.
+type LinearBackoff struct {
+	BaseDelay time.Duration
+	Increment time.Duration
+	MaxDelay  time.Duration
+}
+
+func (b LinearBackoff) Delay(retries int, _ time.Duration) time.Duration {
+	delay := b.BaseDelay + time.Duration(retries)*b.Increment
+	if delay > b.MaxDelay {
+		delay = b.MaxDelay
+	}
+	return delay
+}
+
+// DecorrelatedJitterBackoff implements the "decorrelated jitter" backoff
+// from AWS's retry guidance: each delay is drawn uniformly from
+// [BaseDelay, prevDelay*3], capped at MaxDelay, which spreads out retries
+// more than a plain exponential backoff under contention.
+type DecorrelatedJitterBackoff struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+func (b DecorrelatedJitterBackoff) Delay(_ int, prevDelay time.Duration) time.Duration {
+	prev := prevDelay
+	if prev == 0 {
+		prev = b.BaseDelay
+	}
+	upper := float64(prev) * 3
+	delay := float64(b.BaseDelay) + rand.Float64()*(upper-float64(b.BaseDelay))
+	if delay > float64(b.MaxDelay) {
+		delay = float64(b.MaxDelay)
+	}
+	return time.Duration(delay)
+}
+
+// AckLevel controls how many nodes must acknowledge a broadcast leg before
+// NewQuorumBroadcastInterceptor returns the local response to the client.
+type AckLevel int
+
+const (
+	// AckNone returns as soon as the local call completes, without waiting
+	// on any remote node - equivalent to BroadcastInterceptor's fire-and-forget
+	// behavior.
+	AckNone AckLevel = iota
+	// AckOne waits for the first successful remote acknowledgment.
+	AckOne
+	// AckQuorum waits until QuorumSpec.Enough reports a satisfied quorum
+	// (by default, a strict majority of nodes).
+	AckQuorum
+	// AckAll waits for every node in the configuration to acknowledge.
+	AckAll
+)
+
+// PartialSuccessPolicy decides what NewQuorumBroadcastInterceptor returns to
+// the client when the ack timeout elapses without enough acknowledgments.
+type PartialSuccessPolicy int
+
+const (
+	// PartialSuccessError returns a BroadcastError describing the per-node
+	// failures.
+	PartialSuccessError PartialSuccessPolicy = iota
+	// PartialSuccessLastSuccess returns the most recent successful remote
+	// response instead of failing the call.
+	PartialSuccessLastSuccess
+	// PartialSuccessMerge returns QuorumSpec.Merge's combination of whatever
+	// successful responses did arrive.
+	PartialSuccessMerge
+)
+
+// BroadcastError aggregates the per-node errors collected while waiting for
+// acknowledgments of a quorum broadcast.
+type BroadcastError struct {
+	// NodeErrors maps a node's address to the error it returned.
+	NodeErrors map[string]error
+}
+
+func (e *BroadcastError) Error() string {
+	return fmt.Sprintf("broadcast: %d node(s) failed to acknowledge: %v", len(e.NodeErrors), e.NodeErrors)
+}
+
+// QuorumSpec decides, from the acknowledgments collected so far, whether a
+// quorum broadcast has received enough of them, and how to combine responses
+// when PartialSuccessMerge is configured. Implementations are typically
+// generated alongside a service's gorums quorum-call specs.
+type QuorumSpec interface {
+	// Enough reports whether responses satisfies level given total nodes in
+	// the configuration.
+	Enough(level AckLevel, total int, responses []*gorums.Message) bool
+	// Merge combines responses into a single reply for PartialSuccessMerge.
+	Merge(responses []*gorums.Message) (*gorums.Message, error)
+}
+
+// QuorumBroadcastOption configures a QuorumBroadcastInterceptor created by
+// NewQuorumBroadcastInterceptor.
+type QuorumBroadcastOption func(*quorumBroadcastConfig)
+
+type quorumBroadcastConfig struct {
+	ackLevel       AckLevel
+	timeout        time.Duration
+	partialSuccess PartialSuccessPolicy
+
+	healthInterval time.Duration
+	healthService  string
+
+	dedup    DedupStore
+	dedupTTL time.Duration
+}
+
+// WithAckLevel sets how many acknowledgments a quorum broadcast waits for
+// before returning. The default is AckQuorum.
+func WithAckLevel(level AckLevel) QuorumBroadcastOption {
+	return func(c *quorumBroadcastConfig) { c.ackLevel = level }
+}
+
+// WithAckTimeout bounds how long a quorum broadcast waits for acknowledgments
+// before falling back to its PartialSuccessPolicy. The default is 5 seconds.
+func WithAckTimeout(d time.Duration) QuorumBroadcastOption {
+	return func(c *quorumBroadcastConfig) { c.timeout = d }
+}
+
+// WithPartialSuccessPolicy sets what a quorum broadcast returns when its
+// timeout elapses without enough acknowledgments. The default is
+// PartialSuccessError.
+func WithPartialSuccessPolicy(p PartialSuccessPolicy) QuorumBroadcastOption {
+	return func(c *quorumBroadcastConfig) { c.partialSuccess = p }
+}
+
+// WithQuorumHealthCheck makes a QuorumBroadcastInterceptor consult a
+// background HealthChecker before waiting on a node's acknowledgment,
+// treating a node the checker reports NOT_SERVING for as an immediate
+// failed ack instead of waiting out its ack timeout. It shares the same
+// HealthChecker type as BroadcastInterceptor's WithHealthCheck.
+func WithQuorumHealthCheck(interval time.Duration, service string) QuorumBroadcastOption {
+	return func(c *quorumBroadcastConfig) {
+		c.healthInterval = interval
+		c.healthService = service
+	}
+}
+
+// WithQuorumDedupStore makes a QuorumBroadcastInterceptor consult store
+// before originating a broadcast, sharing the same DedupStore abstraction as
+// BroadcastInterceptor's WithDedupStore so a restarted server doesn't
+// re-broadcast a message it already dispatched before it crashed. The
+// message is marked before dispatch, whether or not enough acks later
+// arrive. The default is an in-process MemDedupStore, which offers no
+// protection across restarts.
+func WithQuorumDedupStore(store DedupStore) QuorumBroadcastOption {
+	return func(c *quorumBroadcastConfig) { c.dedup = store }
+}
+
+// QuorumBroadcastInterceptor broadcasts incoming requests for a given method
+// to all nodes in a configuration and waits for a configurable number of them
+// to acknowledge before returning the local response to the client, unlike
+// BroadcastInterceptor's fire-and-forget dispatch. It shares
+// BroadcastInterceptor's wire propagation (outgoingBroadcastContext /
+// forwardedBroadcastID), HealthChecker, and DedupStore building blocks, but
+// does not retry failed legs in the background via BackoffStrategy: waiting
+// for acks already gives the caller a synchronous, bounded-by-timeout signal
+// of what succeeded, and layering an async retry underneath it would let a
+// "failed" ack silently succeed later with no way to surface that to a
+// client who already got an error back.
+type QuorumBroadcastInterceptor struct {
+	cfg    gorums.Configuration
+	method string
+	quorum QuorumSpec
+	quorumBroadcastConfig
+
+	health *HealthChecker
+}
+
+// NewQuorumBroadcastInterceptor creates a QuorumBroadcastInterceptor that
+// broadcasts incoming requests for method to all nodes in cfg, using quorum
+// to judge when enough nodes have acknowledged. Register its Intercept method
+// with gorums.WithInterceptors.
+func NewQuorumBroadcastInterceptor(cfg gorums.Configuration, method string, quorum QuorumSpec, opts ...QuorumBroadcastOption) *QuorumBroadcastInterceptor {
+	c := quorumBroadcastConfig{
+		ackLevel: AckQuorum,
+		timeout:  5 * time.Second,
+		dedup:    NewMemDedupStore(),
+		dedupTTL: defaultDedupTTL,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	q := &QuorumBroadcastInterceptor{
+		cfg:                   cfg,
+		method:                method,
+		quorum:                quorum,
+		quorumBroadcastConfig: c,
+	}
+	if c.healthInterval > 0 {
+		q.health = NewHealthChecker(cfg, c.healthService, c.healthInterval)
+	}
+	return q
+}
+
+// requiredAcks returns how many of total nodes must acknowledge before q's
+// ack level is satisfied, for callers that don't supply a QuorumSpec.
+func (q *QuorumBroadcastInterceptor) requiredAcks(total int) int {
+	switch q.ackLevel {
+	case AckNone:
+		return 0
+	case AckOne:
+		return 1
+	case AckAll:
+		return total
+	default: // AckQuorum
+		return total/2 + 1
+	}
+}
+
+// acksSufficient reports whether responses satisfy level. A caller-supplied
+// quorum, when present, is the sole judge of sufficiency - it may want more
+// than a bare majority, or a check of response content requiredAcks knows
+// nothing about - so it is never short-circuited by responses merely
+// reaching required; only in the absence of a QuorumSpec does the generic
+// majority count decide.
+func acksSufficient(quorum QuorumSpec, level AckLevel, total, required int, responses []*gorums.Message) bool {
+	if quorum != nil {
+		return quorum.Enough(level, total, responses)
+	}
+	return len(responses) >= required
+}
+
+// onInsufficientAcks decides what to return once q's ack timeout elapses
+// without enough acknowledgments, applying q's PartialSuccessPolicy.
+func (q *QuorumBroadcastInterceptor) onInsufficientAcks(local *gorums.Message, responses []*gorums.Message, nodeErrors map[string]error) (*gorums.Message, error) {
+	switch q.partialSuccess {
+	case PartialSuccessLastSuccess:
+		if len(responses) > 0 {
+			return responses[len(responses)-1], nil
+		}
+	case PartialSuccessMerge:
+		if q.quorum != nil && len(responses) > 0 {
+			if merged, err := q.quorum.Merge(responses); err == nil {
+				return merged, nil
 			}
+		}
+	}
+	return local, &BroadcastError{NodeErrors: nodeErrors}
+}
 
-			wg.Wait()
-			// Broadcast complete (no logging)
-		}()
+// dispatchNoAck broadcasts msg to every node in nodes without waiting on any
+// of their responses, for AckLevel AckNone. It does not retry failed legs:
+// like the rest of QuorumBroadcastInterceptor, it leaves that to the caller
+// rather than silently retrying behind an ack the client already gave up on.
+func (q *QuorumBroadcastInterceptor) dispatchNoAck(nodes []*gorums.Node, msg proto.Message, id string) {
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		if q.health != nil && q.health.State(node.Address()) == HealthNotServing {
+			continue
+		}
+		wg.Add(1)
+		go func(n *gorums.Node) {
+			defer wg.Done()
+			nodeCtx := outgoingBroadcastContext(n.Context(context.Background()), id)
+			_, _ = gorums.RPCCall(nodeCtx, msg, q.method)
+		}(node)
+	}
+	wg.Wait()
+}
+
+// Intercept implements gorums.Interceptor. Register it via
+// gorums.WithInterceptors(q.Intercept).
+func (q *QuorumBroadcastInterceptor) Intercept(ctx gorums.ServerCtx, msg *gorums.Message, next gorums.Handler) (*gorums.Message, error) {
+	if msg.GetMethod() != q.method {
+		return next(ctx, msg)
+	}
+	if _, forwarded := forwardedBroadcastID(ctx, msg); forwarded {
+		return next(ctx, msg)
+	}
+
+	// id is the per-call registry/wire ID (see broadcastID); dedupKey is
+	// the separate, content-derived key the DedupStore checks, since it
+	// must still recognize this message if this server crashes and the
+	// client retries with a new message ID (see dedupKeyFor).
+	id := broadcastID(msg)
+	dedupKey := dedupKeyFor(msg)
+	if q.dedup != nil && q.dedup.Seen(dedupKey) {
+		return next(ctx, msg)
+	}
 
+	resp, err := next(ctx, msg)
+	if err != nil {
 		return resp, err
 	}
+
+	nodes := q.cfg.Nodes()
+	if len(nodes) == 0 {
+		return resp, nil
+	}
+
+	if q.dedup != nil {
+		q.dedup.Mark(dedupKey, q.dedupTTL)
+	}
+
+	msgCopy := proto.Clone(msg.GetProtoMessage())
+
+	if q.ackLevel == AckNone {
+		// Still dispatch to every node - AckNone only means the caller
+		// doesn't wait on any of them, not that nothing is sent.
+		go q.dispatchNoAck(nodes, msgCopy, id)
+		return resp, nil
+	}
+
+	// dispatchCtx bounds the RPCs to each node and is independent of how
+	// long this call waits on them: it must not be cancelled just because
+	// the required number of acks has already come in, or the nodes that
+	// haven't acked yet have their in-flight delivery torn down purely
+	// because other nodes were faster. It is only cancelled once every
+	// dispatch goroutine has actually finished, plus its own timeout as a
+	// backstop against a node that never replies.
+	dispatchCtx, cancelDispatch := context.WithTimeout(context.Background(), q.timeout)
+
+	type ack struct {
+		node string
+		resp *gorums.Message
+		err  error
+	}
+	acks := make(chan ack, len(nodes))
+	var dispatchWG sync.WaitGroup
+	for _, node := range nodes {
+		dispatchWG.Add(1)
+		go func(n *gorums.Node) {
+			defer dispatchWG.Done()
+			if q.health != nil && q.health.State(n.Address()) == HealthNotServing {
+				acks <- ack{node: n.Address(), err: fmt.Errorf("node %s is not serving", n.Address())}
+				return
+			}
+			nodeCtx := outgoingBroadcastContext(n.Context(dispatchCtx), id)
+			r, callErr := gorums.RPCCall(nodeCtx, msgCopy, q.method)
+			acks <- ack{node: n.Address(), resp: r, err: callErr}
+		}(node)
+	}
+	go func() {
+		dispatchWG.Wait()
+		cancelDispatch()
+	}()
+
+	// waitCtx bounds only how long this call blocks waiting for acks; it
+	// does not reach the dispatch goroutines, so returning once enough
+	// acks are in (or once it expires) never cancels delivery to the
+	// slower, not-yet-acked nodes.
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), q.timeout)
+	defer cancelWait()
+
+	responses := make([]*gorums.Message, 0, len(nodes))
+	nodeErrors := make(map[string]error)
+	required := q.requiredAcks(len(nodes))
+
+	for range nodes {
+		select {
+		case a := <-acks:
+			if a.err != nil {
+				nodeErrors[a.node] = a.err
+				continue
+			}
+			responses = append(responses, a.resp)
+			if acksSufficient(q.quorum, q.ackLevel, len(nodes), required, responses) {
+				return resp, nil
+			}
+		case <-waitCtx.Done():
+			return q.onInsufficientAcks(resp, responses, nodeErrors)
+		}
+	}
+
+	return q.onInsufficientAcks(resp, responses, nodeErrors)
+}
+
+// RegisterBroadcastCancelRPC registers the secondary RPC a receiving node
+// must expose so that BroadcastInterceptor.CancelBroadcast can reach it: when
+// the originating server cancels a broadcast, it calls method+"Cancel" on
+// each node whose leg was still in flight or awaiting retry, carrying the
+// same "broadcastID" metadata entry as the original message; nodes whose leg
+// had already completed are not called. onCancel is invoked with that ID so
+// the node can abort any downstream work the original request triggered.
+func RegisterBroadcastCancelRPC(srv *gorums.Server, method string, onCancel func(id string)) {
+	srv.RegisterHandler(method+"Cancel", func(ctx gorums.ServerCtx, msg *gorums.Message) (*gorums.Message, error) {
+		if id, ok := forwardedBroadcastID(ctx, msg); ok {
+			onCancel(id)
+		}
+		return msg, nil
+	})
 }
 
 // SelectiveBroadcastInterceptor creates an interceptor that conditionally broadcasts based
@@ -254,3 +1433,390 @@ func NewSelectiveBroadcastInterceptor(cfg gorums.Configuration, method string, s
 		return resp, err
 	}
 }
+
+// HealthState is a node's last-known serving state, as reported by its most
+// recent grpc.health.v1.Health/Check probe.
+type HealthState int32
+
+const (
+	// HealthUnknown means the node has not yet answered its first health
+	// probe. Once a node has answered at least one probe, it only ever
+	// moves between HealthServing and HealthNotServing - see
+	// maxConsecutiveHealthTimeouts.
+	HealthUnknown HealthState = iota
+	HealthServing
+	HealthNotServing
+)
+
+// HealthTransition is sent to a HealthChecker's subscribers whenever a
+// node's serving state changes.
+type HealthTransition struct {
+	Node  string
+	State HealthState
+}
+
+// maxConsecutiveHealthTimeouts is how many probes in a row must fail before
+// a node is treated as HealthNotServing, so that a single slow probe doesn't
+// flap a node's state. A node that was never reachable behaves the same way:
+// after this many timeouts it is NotServing, not merely Unknown, so the
+// broadcast dispatch loop actually skips it instead of hanging on a dial
+// timeout against it on every broadcast.
+const maxConsecutiveHealthTimeouts = 3
+
+// HealthChecker polls grpc.health.v1.Health/Check on every node of a
+// configuration at a fixed interval and keeps each node's last-known serving
+// state available for lock-free reads from a broadcast dispatch loop via
+// State. Subscribe lets other code, such as a future leader-election layer,
+// react to SERVING/NOT_SERVING transitions.
+type HealthChecker struct {
+	service  string
+	interval time.Duration
+
+	states sync.Map // node address -> *atomic.Value of HealthState
+	fails  sync.Map // node address -> *int32 consecutive timeout counter
+
+	subsMu sync.Mutex
+	subs   []chan HealthTransition
+}
+
+// NewHealthChecker starts a background probe goroutine per node in cfg,
+// checking service every interval, and returns a HealthChecker that reflects
+// their results.
+func NewHealthChecker(cfg gorums.Configuration, service string, interval time.Duration) *HealthChecker {
+	hc := &HealthChecker{service: service, interval: interval}
+	for _, node := range cfg.Nodes() {
+		hc.stateFor(node.Address()).Store(HealthUnknown)
+		go hc.run(node)
+	}
+	return hc
+}
+
+func (hc *HealthChecker) stateFor(addr string) *atomic.Value {
+	v, _ := hc.states.LoadOrStore(addr, new(atomic.Value))
+	return v.(*atomic.Value)
+}
+
+func (hc *HealthChecker) failsFor(addr string) *int32 {
+	v, _ := hc.fails.LoadOrStore(addr, new(int32))
+	return v.(*int32)
+}
+
+func (hc *HealthChecker) run(n *gorums.Node) {
+	ticker := time.NewTicker(hc.interval)
+	defer ticker.Stop()
+
+	client := healthpb.NewHealthClient(n.Conn())
+	addr := n.Address()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), hc.interval)
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: hc.service})
+		cancel()
+
+		var status healthpb.HealthCheckResponse_ServingStatus
+		if resp != nil {
+			status = resp.GetStatus()
+		}
+
+		fails := hc.failsFor(addr)
+		next, newFails, ok := healthProbeOutcome(atomic.LoadInt32(fails), err, status)
+		atomic.StoreInt32(fails, newFails)
+		if !ok {
+			continue // a single blip shouldn't downgrade the node's state
+		}
+
+		state := hc.stateFor(addr)
+		if prev, _ := state.Load().(HealthState); prev != next {
+			state.Store(next)
+			hc.notify(HealthTransition{Node: addr, State: next})
+		}
+	}
+}
+
+// healthProbeOutcome decides the next consecutive-failure count for a node
+// and, once that reaches maxConsecutiveHealthTimeouts, the HealthState a
+// failing probe should transition it to. It is split out of run as a pure
+// function so the state-machine logic - in particular, when repeated
+// failures graduate from a blip to NotServing - can be tested without a real
+// gRPC health client.
+//
+// ok is false when probeErr is set but fails hasn't yet reached the
+// threshold: run should leave the node's current state alone rather than
+// downgrade it on a single blip. A successful probe always reports ok=true
+// and resets the failure count to 0; status is only consulted in that case.
+func healthProbeOutcome(fails int32, probeErr error, status healthpb.HealthCheckResponse_ServingStatus) (next HealthState, newFails int32, ok bool) {
+	if probeErr != nil {
+		newFails = fails + 1
+		if newFails < maxConsecutiveHealthTimeouts {
+			return 0, newFails, false
+		}
+		// A node that keeps failing to answer - dial refused, timed out,
+		// whatever - is treated the same as one that explicitly reported
+		// NOT_SERVING, so the broadcast dispatch loop (which only checks
+		// for HealthNotServing) actually skips it instead of waiting out
+		// a dial timeout against it every time.
+		return HealthNotServing, newFails, true
+	}
+	if status == healthpb.HealthCheckResponse_SERVING {
+		return HealthServing, 0, true
+	}
+	return HealthNotServing, 0, true
+}
+
+func (hc *HealthChecker) notify(t HealthTransition) {
+	hc.subsMu.Lock()
+	defer hc.subsMu.Unlock()
+	for _, ch := range hc.subs {
+		select {
+		case ch <- t:
+		default:
+			// A slow subscriber shouldn't block health polling for everyone
+			// else; it just misses this transition.
+		}
+	}
+}
+
+// Subscribe returns a channel that receives a HealthTransition each time a
+// node's serving state changes.
+func (hc *HealthChecker) Subscribe() <-chan HealthTransition {
+	ch := make(chan HealthTransition, 16)
+	hc.subsMu.Lock()
+	hc.subs = append(hc.subs, ch)
+	hc.subsMu.Unlock()
+	return ch
+}
+
+// State returns addr's last-known serving state, or HealthUnknown if it has
+// never been probed.
+func (hc *HealthChecker) State(addr string) HealthState {
+	v, ok := hc.states.Load(addr)
+	if !ok {
+		return HealthUnknown
+	}
+	state, _ := v.(*atomic.Value).Load().(HealthState)
+	return state
+}
+
+// DedupStore tracks which broadcasts a BroadcastInterceptor has already
+// dispatched, so it can avoid re-dispatching the same one - including after
+// a crash and restart, if the implementation is persistent. The key
+// Intercept marks and checks is derived from the message's content (see
+// dedupKeyFor), not the per-call broadcastID used elsewhere for the
+// registry and wire propagation: a crash-and-retry arrives with a new
+// message ID, so only a content-derived key still recognizes it as the
+// same request. The trade-off is that two different clients broadcasting
+// byte-identical requests collide in a DedupStore, unlike in the registry;
+// see the Crash Recovery section on NewBroadcastInterceptor.
+//
+// Implementations must be safe for concurrent use.
+type DedupStore interface {
+	// Seen reports whether id has already been marked and hasn't expired.
+	Seen(id string) bool
+	// Mark records id as seen, to expire after ttl.
+	Mark(id string, ttl time.Duration)
+}
+
+// MemDedupStore is an in-memory DedupStore backed by a map of expiry times
+// plus a time-ordered ring of IDs, so expired entries are reclaimed from the
+// front of the ring in the order they were marked instead of requiring a
+// full scan or a size-based cutoff. It does not survive a process restart.
+type MemDedupStore struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+	ring    []string
+}
+
+// NewMemDedupStore creates an empty MemDedupStore.
+func NewMemDedupStore() *MemDedupStore {
+	return &MemDedupStore{expires: make(map[string]time.Time)}
+}
+
+func (s *MemDedupStore) Seen(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reclaimLocked()
+	deadline, ok := s.expires[id]
+	return ok && time.Now().Before(deadline)
+}
+
+func (s *MemDedupStore) Mark(id string, ttl time.Duration) {
+	s.markUntil(id, time.Now().Add(ttl))
+}
+
+// markUntil is the shared entry point FileDedupStore uses to replay
+// deadlines read back from disk, where the deadline is already known rather
+// than computed from a TTL relative to now.
+func (s *MemDedupStore) markUntil(id string, deadline time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.expires[id]; !exists {
+		s.ring = append(s.ring, id)
+	}
+	s.expires[id] = deadline
+	s.reclaimLocked()
+}
+
+// reclaimLocked drops entries from the front of the ring whose TTL has
+// passed. Callers must hold s.mu.
+func (s *MemDedupStore) reclaimLocked() {
+	now := time.Now()
+	for len(s.ring) > 0 {
+		id := s.ring[0]
+		if deadline, ok := s.expires[id]; ok && now.Before(deadline) {
+			break
+		}
+		delete(s.expires, id)
+		s.ring = s.ring[1:]
+	}
+}
+
+// lruEntry is one node of LRUDedupStore's recency list.
+type lruEntry struct {
+	id       string
+	deadline time.Time
+}
+
+// LRUDedupStore is a DedupStore that keeps at most capacity IDs, evicting
+// the least recently marked-or-seen entry once full, rather than relying on
+// TTL expiry to bound its size. It does not survive a process restart.
+type LRUDedupStore struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	elems    map[string]*list.Element
+}
+
+// NewLRUDedupStore creates an LRUDedupStore that holds at most capacity IDs.
+func NewLRUDedupStore(capacity int) *LRUDedupStore {
+	return &LRUDedupStore{
+		capacity: capacity,
+		order:    list.New(),
+		elems:    make(map[string]*list.Element),
+	}
+}
+
+func (s *LRUDedupStore) Seen(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.elems[id]
+	if !ok {
+		return false
+	}
+	entry := el.Value.(*lruEntry)
+	if time.Now().After(entry.deadline) {
+		s.order.Remove(el)
+		delete(s.elems, id)
+		return false
+	}
+	s.order.MoveToFront(el)
+	return true
+}
+
+func (s *LRUDedupStore) Mark(id string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deadline := time.Now().Add(ttl)
+	if el, ok := s.elems[id]; ok {
+		el.Value.(*lruEntry).deadline = deadline
+		s.order.MoveToFront(el)
+		return
+	}
+
+	el := s.order.PushFront(&lruEntry{id: id, deadline: deadline})
+	s.elems[id] = el
+	if s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.elems, oldest.Value.(*lruEntry).id)
+	}
+}
+
+// FileDedupStore is a DedupStore backed by an append-only file, so a
+// restarting server doesn't re-broadcast a message it already propagated
+// before a crash. Each Mark call appends one "id\tdeadlineUnixNano" line;
+// NewFileDedupStore replays the file to rebuild the in-memory index that
+// Seen reads from, so restart cost is proportional to the file's size -
+// an embedded KV store such as BoltDB would avoid that replay, at the cost
+// of a storage dependency this module doesn't otherwise need.
+type FileDedupStore struct {
+	mu   sync.Mutex
+	file *os.File
+	mem  *MemDedupStore
+}
+
+// NewFileDedupStore opens (creating if necessary) the dedup log at path and
+// replays it to recover state from before a possible crash.
+func NewFileDedupStore(path string) (*FileDedupStore, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("dedup: open %s: %w", path, err)
+	}
+
+	mem := NewMemDedupStore()
+	if err := replayDedupFile(f, mem); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &FileDedupStore{file: f, mem: mem}, nil
+}
+
+func replayDedupFile(f *os.File, mem *MemDedupStore) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		id, deadline, ok := parseDedupLine(scanner.Text())
+		if !ok {
+			continue // skip a line left truncated by a crash mid-write
+		}
+		if time.Now().Before(deadline) {
+			mem.markUntil(id, deadline)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	_, err := f.Seek(0, io.SeekEnd)
+	return err
+}
+
+func parseDedupLine(line string) (id string, deadline time.Time, ok bool) {
+	key, value, found := strings.Cut(line, "\t")
+	if !found {
+		return "", time.Time{}, false
+	}
+	nanos, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return key, time.Unix(0, nanos), true
+}
+
+func (s *FileDedupStore) Seen(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mem.Seen(id)
+}
+
+func (s *FileDedupStore) Mark(id string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deadline := time.Now().Add(ttl)
+	s.mem.markUntil(id, deadline)
+	fmt.Fprintf(s.file, "%s\t%d\n", id, deadline.UnixNano())
+}
+
+// Close closes the underlying file. The FileDedupStore must not be used
+// afterward.
+func (s *FileDedupStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -6,14 +6,33 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/relab/gorums"
+	"github.com/relab/gorums/examples/interceptors"
 )
 
+// parseAckLevel maps the -ack flag's value to an interceptors.AckLevel,
+// defaulting to AckQuorum for unrecognized values.
+func parseAckLevel(s string) interceptors.AckLevel {
+	switch strings.ToLower(s) {
+	case "none":
+		return interceptors.AckNone
+	case "one":
+		return interceptors.AckOne
+	case "all":
+		return interceptors.AckAll
+	default:
+		return interceptors.AckQuorum
+	}
+}
+
 func main() {
 	server := flag.String("server", "", "Start as a server on given address.")
 	remotes := flag.String("connect", "", "Comma-separated list of servers to connect to.")
 	broadcast := flag.Bool("broadcast", false, "Enable broadcast interceptor (replicates writes to all nodes).")
+	ack := flag.String("ack", "quorum", "Broadcast acknowledgment level: none, one, quorum, or all.")
+	healthInterval := flag.Duration("health-interval", time.Second, "Health-check interval for broadcast mode; nodes are skipped once they report NOT_SERVING or fail 3 probes in a row.")
 	flag.Parse()
 
 	if *server != "" {
@@ -40,7 +59,8 @@ func main() {
 				listeners[i] = fmt.Sprintf("127.0.0.1:%d", 50000+i)
 			}
 
-			log.Println("Starting servers with broadcast configuration...")
+			ackLevel := parseAckLevel(*ack)
+			log.Printf("Starting servers with broadcast configuration (ack=%s)...\n", *ack)
 			for i, addr := range listeners {
 				// Each server broadcasts to all OTHER servers
 				otherNodes := make([]string, 0, len(listeners)-1)
@@ -49,7 +69,7 @@ func main() {
 						otherNodes = append(otherNodes, otherAddr)
 					}
 				}
-				srv, realAddr := startServerWithBroadcast(addr, otherNodes)
+				srv, realAddr := startServerWithBroadcast(addr, otherNodes, ackLevel, *healthInterval)
 				srvs = append(srvs, srv)
 				addrs = append(addrs, realAddr)
 				log.Printf("Started server %d on %s with broadcast to %d nodes\n", i, realAddr, len(otherNodes))